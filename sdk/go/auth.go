@@ -0,0 +1,291 @@
+package kvdb
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/kvdb/proto"
+)
+
+// Permission 描述一个角色对某个键前缀拥有的权限
+type Permission int32
+
+const (
+	PermissionRead Permission = iota
+	PermissionWrite
+	PermissionReadWrite
+)
+
+// TLSInfo 描述建立 TLS 连接所需的证书材料，镜像 etcd 的 transport.TLSInfo
+type TLSInfo struct {
+	CertFile      string
+	KeyFile       string
+	TrustedCAFile string
+}
+
+// ClientConfig 返回值用的 TLS 配置由调用方通过 ClientConfig.TLS 传入；TLSInfo.ClientConfig 根据 TLSInfo
+// 构造一个可以直接赋给 ClientConfig.TLS 的 *tls.Config
+func (info TLSInfo) ClientConfig() (*tls.Config, error) {
+	cfg := &tls.Config{}
+
+	if info.CertFile != "" || info.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(info.CertFile, info.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("kvdb: failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if info.TrustedCAFile != "" {
+		caData, err := os.ReadFile(info.TrustedCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("kvdb: failed to read trusted CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("kvdb: failed to parse trusted CA file: %s", info.TrustedCAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// authToken 保存 Authenticate 获得的 bearer token，每次 RPC 调用前附加到 gRPC metadata 上
+type authToken struct {
+	mu    sync.RWMutex
+	token string
+}
+
+func (a *authToken) set(token string) {
+	a.mu.Lock()
+	a.token = token
+	a.mu.Unlock()
+}
+
+func (a *authToken) get() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.token
+}
+
+// Authenticate 使用 ClientConfig.Username/Password 登录并获取 bearer token，
+// 此后的每次 RPC 调用都会在 gRPC metadata 中携带该 token
+func (c *Client) Authenticate(ctx context.Context) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+	if c.config.Username == "" {
+		return fmt.Errorf("kvdb: username is required to authenticate")
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	defer cancel()
+
+	rpcClient, err := c.rpc()
+	if err != nil {
+		return err
+	}
+
+	resp, err := rpcClient.Authenticate(reqCtx, &pb.AuthenticateRequest{
+		Username: c.config.Username,
+		Password: c.config.Password,
+	})
+	if err != nil {
+		return fmt.Errorf("authenticate operation failed: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return fmt.Errorf("authenticate operation failed: %s", resp.ErrorMessage)
+	}
+
+	c.authToken.set(resp.Token)
+	return nil
+}
+
+// withAuth 将当前持有的 bearer token 作为 gRPC metadata 附加到 ctx 上
+func (c *Client) withAuth(ctx context.Context) context.Context {
+	token := c.authToken.get()
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// Auth 是认证管理相关操作的命名空间，通过 Client.Auth 访问
+type Auth struct {
+	client *Client
+}
+
+// Auth 返回认证管理 API
+func (c *Client) Auth() *Auth {
+	return &Auth{client: c}
+}
+
+// Enable 为集群开启认证；开启后未携带有效 token 的请求会被拒绝
+func (a *Auth) Enable(ctx context.Context) error {
+	return a.toggle(ctx, true)
+}
+
+// Disable 为集群关闭认证
+func (a *Auth) Disable(ctx context.Context) error {
+	return a.toggle(ctx, false)
+}
+
+func (a *Auth) toggle(ctx context.Context, enabled bool) error {
+	c := a.client
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.AuthEnableResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.AuthEnable(rctx, &pb.AuthEnableRequest{Enabled: enabled})
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("auth enable/disable operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("auth enable/disable operation failed: %s", resp.ErrorMessage)
+	}
+	return nil
+}
+
+// UserAdd 创建一个新用户
+func (a *Auth) UserAdd(ctx context.Context, username, password string) error {
+	c := a.client
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.UserAddResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.UserAdd(rctx, &pb.UserAddRequest{Username: username, Password: password})
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("user add operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("user add operation failed: %s", resp.ErrorMessage)
+	}
+	return nil
+}
+
+// UserDelete 删除一个用户
+func (a *Auth) UserDelete(ctx context.Context, username string) error {
+	c := a.client
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.UserDeleteResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.UserDelete(rctx, &pb.UserDeleteRequest{Username: username})
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("user delete operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("user delete operation failed: %s", resp.ErrorMessage)
+	}
+	return nil
+}
+
+// UserChangePassword 修改用户密码
+func (a *Auth) UserChangePassword(ctx context.Context, username, newPassword string) error {
+	c := a.client
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.UserChangePasswordResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.UserChangePassword(rctx, &pb.UserChangePasswordRequest{
+			Username: username,
+			Password: newPassword,
+		})
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("user change password operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("user change password operation failed: %s", resp.ErrorMessage)
+	}
+	return nil
+}
+
+// RoleAdd 创建一个新角色
+func (a *Auth) RoleAdd(ctx context.Context, role string) error {
+	c := a.client
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.RoleAddResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.RoleAdd(rctx, &pb.RoleAddRequest{Role: role})
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("role add operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("role add operation failed: %s", resp.ErrorMessage)
+	}
+	return nil
+}
+
+// RoleGrantPermission 授予角色对某个键前缀的权限；将角色赋予用户由 UserGrantRole 完成
+func (a *Auth) RoleGrantPermission(ctx context.Context, role, keyPrefix string, perm Permission) error {
+	c := a.client
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.RoleGrantPermissionResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.RoleGrantPermission(rctx, &pb.RoleGrantPermissionRequest{
+			Role:       role,
+			KeyPrefix:  keyPrefix,
+			Permission: pb.Permission(perm),
+		})
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("role grant permission operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("role grant permission operation failed: %s", resp.ErrorMessage)
+	}
+	return nil
+}
+
+// UserGrantRole 将角色赋予用户
+func (a *Auth) UserGrantRole(ctx context.Context, username, role string) error {
+	c := a.client
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.UserGrantRoleResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.UserGrantRole(rctx, &pb.UserGrantRoleRequest{Username: username, Role: role})
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("user grant role operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("user grant role operation failed: %s", resp.ErrorMessage)
+	}
+	return nil
+}
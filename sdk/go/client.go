@@ -2,14 +2,20 @@ package kvdb
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	pb "github.com/kvdb/proto"
 )
 
@@ -34,6 +40,20 @@ type ClientConfig struct {
 	
 	// HTTP特定配置
 	HTTPBaseURL string `json:"http_base_url"`
+
+	// Cluster 非空时，客户端以集群模式运行：在 Peers 中发现当前 leader 并在其变化时自动切换
+	Cluster *ClusterConfig `json:"cluster,omitempty"`
+
+	// Endpoints 非空且 Cluster 为空时，客户端在这些 endpoint 上维护一个连接池，
+	// 轮询负载均衡请求并在某个 endpoint 不可用时自动转移到其他 endpoint
+	Endpoints []string `json:"endpoints,omitempty"`
+
+	// TLS 非空时，Connect 使用 TLS 传输而非明文；通常通过 TLSInfo.ClientConfig() 构造
+	TLS *tls.Config `json:"-"`
+
+	// Username/Password 用于 Authenticate 登录换取 bearer token；为空时不做认证
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
 }
 
 // DefaultClientConfig 返回默认客户端配置
@@ -73,6 +93,9 @@ type ScanOptions struct {
 // Snapshot 快照
 type Snapshot struct {
 	ID uint64 `json:"id"`
+
+	// Revision 创建快照时的 MVCC revision，GetAtSnapshot 以此而非 ID 定位数据版本
+	Revision int64 `json:"revision"`
 }
 
 // DatabaseStats 数据库统计信息
@@ -89,17 +112,46 @@ type SubscriptionEvent struct {
 	Value     string `json:"value"`
 	Operation string `json:"operation"`
 	Timestamp uint64 `json:"timestamp"`
+
+	// Revision 该事件发生时的 MVCC revision，可用于 SubscriptionOptions.StartRevision 续传
+	Revision int64 `json:"revision"`
 }
 
 // SubscriptionCallback 订阅回调函数
 type SubscriptionCallback func(event *SubscriptionEvent)
 
+// SubscriptionOptions 配置 Subscribe 的行为
+type SubscriptionOptions struct {
+	// Pattern 要订阅的键模式，例如 "user:*"
+	Pattern string
+	// IncludeDeletes 为 true 时订阅也会收到 delete 事件
+	IncludeDeletes bool
+
+	// StartRevision 非零时，服务端会先从该 revision（闭区间，即该 revision 本身也会被重放）
+	// 开始重放历史事件，再切换到实时推送；通常取自上一次 Subscription.LastRevision()+1 以在
+	// 重连后不丢不重地衔接
+	StartRevision int64
+	// ReplayFromOldest 为 true 时从服务端保留的最早 revision 开始重放，忽略 StartRevision
+	ReplayFromOldest bool
+
+	// ErrorHandler 仅在订阅遇到不可恢复的错误（例如 ErrCompacted）时被调用一次；
+	// 瞬时的流错误会被自动重连吸收，不会触发它
+	ErrorHandler func(error)
+	// BufferSize 不被 Subscribe 读取：事件在接收 goroutine 中直接交付给回调，没有内部事件缓冲区
+	BufferSize int
+}
+
+// ErrCompacted 表示订阅者落后太多，请求重放的 revision 已经被 Compact 清理
+var ErrCompacted = errors.New("kvdb: subscriber has fallen too far behind, requested revision has been compacted")
+
 // Subscription 订阅句柄
 type Subscription struct {
-	id       int32
-	client   *Client
-	cancel   context.CancelFunc
-	active   int32
+	id     int32
+	client *Client
+	cancel context.CancelFunc
+	active int32
+
+	lastRevision int64
 }
 
 // Cancel 取消订阅
@@ -115,18 +167,49 @@ func (s *Subscription) IsActive() bool {
 	return atomic.LoadInt32(&s.active) == 1
 }
 
+// LastRevision 返回已观察到的最后一个事件的 revision，可用于下游应用记录消费位点，
+// 或者在需要重建订阅时作为新的 StartRevision
+func (s *Subscription) LastRevision() int64 {
+	return atomic.LoadInt64(&s.lastRevision)
+}
+
 // Client KVDB客户端
 type Client struct {
 	config *ClientConfig
+
+	// connMu 保护 conn/client/leaderAddr：集群模式下 discoverLeader 会在 rediscovery 时并发地
+	// 与正在进行的 RPC 一起读写这几个字段，裸读写会产生 torn read
+	connMu sync.RWMutex
 	conn   *grpc.ClientConn
 	client pb.KVDBServiceClient
-	
+
 	// 订阅管理
 	subscriptions    map[int32]*Subscription
 	subscriptionsMux sync.RWMutex
 	subscriptionID   int32
 	
 	connected int32
+
+	// leaderAddr 集群模式下当前已连接的 leader 地址，非集群模式下为空
+	leaderAddr string
+
+	// pool 多 endpoint 模式下的连接池，单地址模式下为 nil
+	pool *connPool
+
+	// 租约续约管理
+	leaseKeepAlives    map[uint64]*leaseKeepAlive
+	leaseKeepAlivesMux sync.RWMutex
+
+	// authToken 持有 Authenticate 换取的 bearer token
+	authToken authToken
+}
+
+// transportCredentials 根据 config.TLS 是否设置选择 TLS 或明文传输凭据
+func (config *ClientConfig) transportCredentials() credentials.TransportCredentials {
+	if config.TLS != nil {
+		return credentials.NewTLS(config.TLS)
+	}
+	return insecure.NewCredentials()
 }
 
 // NewClient 创建新的KVDB客户端
@@ -136,8 +219,9 @@ func NewClient(config *ClientConfig) *Client {
 	}
 	
 	return &Client{
-		config:        config,
-		subscriptions: make(map[int32]*Subscription),
+		config:          config,
+		subscriptions:   make(map[int32]*Subscription),
+		leaseKeepAlives: make(map[uint64]*leaseKeepAlive),
 	}
 }
 
@@ -146,37 +230,59 @@ func (c *Client) Connect(ctx context.Context) error {
 	if c.config.Protocol != "grpc" {
 		return fmt.Errorf("unsupported protocol: %s", c.config.Protocol)
 	}
-	
+
+	if c.config.Cluster != nil {
+		if err := c.discoverLeader(ctx); err != nil {
+			return err
+		}
+		atomic.StoreInt32(&c.connected, 1)
+		return nil
+	}
+
+	if len(c.config.Endpoints) > 0 {
+		pool := newConnPool(c.config, c.withAuth)
+		if err := pool.start(ctx, c.config.Endpoints); err != nil {
+			return err
+		}
+		c.pool = pool
+		atomic.StoreInt32(&c.connected, 1)
+		return nil
+	}
+
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(c.config.transportCredentials()),
 		grpc.WithDefaultCallOptions(
 			grpc.MaxCallRecvMsgSize(c.config.MaxRecvMsgSize),
 			grpc.MaxCallSendMsgSize(c.config.MaxSendMsgSize),
 		),
 	}
-	
+
 	if c.config.EnableCompression {
 		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor("gzip")))
 	}
-	
+
 	connectCtx, cancel := context.WithTimeout(ctx, c.config.ConnectionTimeout)
 	defer cancel()
-	
+
 	conn, err := grpc.DialContext(connectCtx, c.config.ServerAddress, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
-	
-	c.conn = conn
-	c.client = pb.NewKVDBServiceClient(conn)
-	
+
+	client := pb.NewKVDBServiceClient(conn)
+
 	// 测试连接
-	_, err = c.client.GetStats(connectCtx, &pb.GetStatsRequest{})
+	_, err = client.GetStats(connectCtx, &pb.GetStatsRequest{})
 	if err != nil {
-		c.conn.Close()
+		conn.Close()
 		return fmt.Errorf("connection test failed: %w", err)
 	}
-	
+
+	c.connMu.Lock()
+	c.conn = conn
+	c.client = client
+	c.connMu.Unlock()
+
 	atomic.StoreInt32(&c.connected, 1)
 	return nil
 }
@@ -186,7 +292,7 @@ func (c *Client) Disconnect() error {
 	if !atomic.CompareAndSwapInt32(&c.connected, 1, 0) {
 		return nil
 	}
-	
+
 	// 取消所有订阅
 	c.subscriptionsMux.Lock()
 	for _, sub := range c.subscriptions {
@@ -194,14 +300,67 @@ func (c *Client) Disconnect() error {
 	}
 	c.subscriptions = make(map[int32]*Subscription)
 	c.subscriptionsMux.Unlock()
-	
-	if c.conn != nil {
-		return c.conn.Close()
+
+	// 停止所有租约续约
+	c.leaseKeepAlivesMux.Lock()
+	for _, ka := range c.leaseKeepAlives {
+		ka.cancel()
 	}
-	
+	c.leaseKeepAlivesMux.Unlock()
+
+	if c.pool != nil {
+		return c.pool.close()
+	}
+
+	c.connMu.RLock()
+	conn := c.conn
+	c.connMu.RUnlock()
+
+	if conn != nil {
+		return conn.Close()
+	}
+
 	return nil
 }
 
+// currentClient 返回当前用于非 pool 模式 RPC 的客户端；集群模式下该值会在 discoverLeader
+// 重新发现 leader 时发生变化，必须每次重新读取而不是缓存调用方自己的副本
+func (c *Client) currentClient() pb.KVDBServiceClient {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.client
+}
+
+// rpc 返回用于发起下一次非 leader-routed RPC 的客户端：多 endpoint 模式下从连接池中
+// 轮询挑选一个健康连接，单连接模式下直接返回 c.client
+func (c *Client) rpc() (pb.KVDBServiceClient, error) {
+	if c.pool != nil {
+		return c.pool.pick()
+	}
+	client := c.currentClient()
+	if client == nil {
+		return nil, fmt.Errorf("client not connected")
+	}
+	return client, nil
+}
+
+// Endpoints 返回多 endpoint 模式下当前连接池覆盖的 endpoint 列表
+func (c *Client) Endpoints() []string {
+	if c.pool == nil {
+		return []string{c.config.ServerAddress}
+	}
+	return c.pool.endpoints()
+}
+
+// SetEndpoints 动态更新多 endpoint 模式下的集群成员：为新增的 endpoint 建立连接，
+// 关闭不再出现在列表中的连接
+func (c *Client) SetEndpoints(ctx context.Context, endpoints []string) error {
+	if c.pool == nil {
+		return fmt.Errorf("kvdb: client is not running in connection-pool mode")
+	}
+	return c.pool.setEndpoints(ctx, endpoints)
+}
+
 // IsConnected 检查连接状态
 func (c *Client) IsConnected() bool {
 	return atomic.LoadInt32(&c.connected) == 1
@@ -213,7 +372,7 @@ func (c *Client) Put(ctx context.Context, key, value string) error {
 		return fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.PutRequest{
@@ -221,7 +380,12 @@ func (c *Client) Put(ctx context.Context, key, value string) error {
 		Value: value,
 	}
 	
-	resp, err := c.client.Put(reqCtx, req)
+	var resp *pb.PutResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Put(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return fmt.Errorf("put operation failed: %w", err)
 	}
@@ -239,16 +403,21 @@ func (c *Client) Get(ctx context.Context, key string) (string, bool, error) {
 		return "", false, fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.GetRequest{Key: key}
-	
-	resp, err := c.client.Get(reqCtx, req)
+
+	var resp *pb.GetResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Get(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return "", false, fmt.Errorf("get operation failed: %w", err)
 	}
-	
+
 	if resp.ErrorMessage != "" {
 		return "", false, fmt.Errorf("get operation failed: %s", resp.ErrorMessage)
 	}
@@ -262,12 +431,17 @@ func (c *Client) Delete(ctx context.Context, key string) error {
 		return fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.DeleteRequest{Key: key}
 	
-	resp, err := c.client.Delete(reqCtx, req)
+	var resp *pb.DeleteResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Delete(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return fmt.Errorf("delete operation failed: %w", err)
 	}
@@ -285,7 +459,7 @@ func (c *Client) BatchPut(ctx context.Context, pairs []KeyValue) error {
 		return fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.BatchPutRequest{}
@@ -296,7 +470,12 @@ func (c *Client) BatchPut(ctx context.Context, pairs []KeyValue) error {
 		})
 	}
 	
-	resp, err := c.client.BatchPut(reqCtx, req)
+	var resp *pb.BatchPutResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.BatchPut(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return fmt.Errorf("batch put operation failed: %w", err)
 	}
@@ -314,16 +493,21 @@ func (c *Client) BatchGet(ctx context.Context, keys []string) ([]KeyValue, error
 		return nil, fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.BatchGetRequest{Keys: keys}
-	
-	resp, err := c.client.BatchGet(reqCtx, req)
+
+	var resp *pb.BatchGetResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.BatchGet(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("batch get operation failed: %w", err)
 	}
-	
+
 	if resp.ErrorMessage != "" {
 		return nil, fmt.Errorf("batch get operation failed: %s", resp.ErrorMessage)
 	}
@@ -345,7 +529,7 @@ func (c *Client) Scan(ctx context.Context, options *ScanOptions) ([]KeyValue, er
 		return nil, fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.ScanRequest{
@@ -354,27 +538,36 @@ func (c *Client) Scan(ctx context.Context, options *ScanOptions) ([]KeyValue, er
 		Limit:    options.Limit,
 	}
 	
-	stream, err := c.client.Scan(reqCtx, req)
-	if err != nil {
-		return nil, fmt.Errorf("scan operation failed: %w", err)
-	}
-	
 	var result []KeyValue
-	for {
-		resp, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		stream, err := rpcClient.Scan(rctx, req)
 		if err != nil {
-			return nil, fmt.Errorf("scan stream error: %w", err)
+			return err
 		}
-		
-		result = append(result, KeyValue{
-			Key:   resp.Key,
-			Value: resp.Value,
-		})
+
+		var items []KeyValue
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			items = append(items, KeyValue{
+				Key:   resp.Key,
+				Value: resp.Value,
+			})
+		}
+
+		result = items
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan operation failed: %w", err)
 	}
-	
+
 	return result, nil
 }
 
@@ -384,7 +577,7 @@ func (c *Client) PrefixScan(ctx context.Context, prefix string, limit int32) ([]
 		return nil, fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.PrefixScanRequest{
@@ -392,27 +585,36 @@ func (c *Client) PrefixScan(ctx context.Context, prefix string, limit int32) ([]
 		Limit:  limit,
 	}
 	
-	stream, err := c.client.PrefixScan(reqCtx, req)
-	if err != nil {
-		return nil, fmt.Errorf("prefix scan operation failed: %w", err)
-	}
-	
 	var result []KeyValue
-	for {
-		resp, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		stream, err := rpcClient.PrefixScan(rctx, req)
 		if err != nil {
-			return nil, fmt.Errorf("prefix scan stream error: %w", err)
+			return err
 		}
-		
-		result = append(result, KeyValue{
-			Key:   resp.Key,
-			Value: resp.Value,
-		})
+
+		var items []KeyValue
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			items = append(items, KeyValue{
+				Key:   resp.Key,
+				Value: resp.Value,
+			})
+		}
+
+		result = items
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("prefix scan operation failed: %w", err)
 	}
-	
+
 	return result, nil
 }
 
@@ -422,12 +624,17 @@ func (c *Client) CreateSnapshot(ctx context.Context) (*Snapshot, error) {
 		return nil, fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.CreateSnapshotRequest{}
 	
-	resp, err := c.client.CreateSnapshot(reqCtx, req)
+	var resp *pb.CreateSnapshotResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.CreateSnapshot(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("create snapshot operation failed: %w", err)
 	}
@@ -436,7 +643,7 @@ func (c *Client) CreateSnapshot(ctx context.Context) (*Snapshot, error) {
 		return nil, fmt.Errorf("create snapshot operation failed: %s", resp.ErrorMessage)
 	}
 	
-	return &Snapshot{ID: resp.SnapshotId}, nil
+	return &Snapshot{ID: resp.SnapshotId, Revision: resp.Revision}, nil
 }
 
 // ReleaseSnapshot 释放快照
@@ -445,16 +652,21 @@ func (c *Client) ReleaseSnapshot(ctx context.Context, snapshot *Snapshot) error
 		return fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.ReleaseSnapshotRequest{SnapshotId: snapshot.ID}
-	
-	resp, err := c.client.ReleaseSnapshot(reqCtx, req)
+
+	var resp *pb.ReleaseSnapshotResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.ReleaseSnapshot(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return fmt.Errorf("release snapshot operation failed: %w", err)
 	}
-	
+
 	if !resp.Success {
 		return fmt.Errorf("release snapshot operation failed: %s", resp.ErrorMessage)
 	}
@@ -462,25 +674,31 @@ func (c *Client) ReleaseSnapshot(ctx context.Context, snapshot *Snapshot) error
 	return nil
 }
 
-// GetAtSnapshot 在快照上读取数据
-func (c *Client) GetAtSnapshot(ctx context.Context, key string, snapshot *Snapshot) (string, bool, error) {
+// GetAtSnapshot 在指定的 MVCC revision 上读取数据，返回该 revision 生效时 key 的值。
+// revision 通常来自 CreateSnapshot 返回的 Snapshot.Revision，也可以是 Get/Txn 观察到的任意历史 revision。
+func (c *Client) GetAtSnapshot(ctx context.Context, key string, revision int64) (string, bool, error) {
 	if !c.IsConnected() {
 		return "", false, fmt.Errorf("client not connected")
 	}
-	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
-	
+
 	req := &pb.GetAtSnapshotRequest{
-		Key:        key,
-		SnapshotId: snapshot.ID,
+		Key:      key,
+		Revision: revision,
 	}
-	
-	resp, err := c.client.GetAtSnapshot(reqCtx, req)
+
+	var resp *pb.GetAtSnapshotResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.GetAtSnapshot(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return "", false, fmt.Errorf("get at snapshot operation failed: %w", err)
 	}
-	
+
 	if resp.ErrorMessage != "" {
 		return "", false, fmt.Errorf("get at snapshot operation failed: %s", resp.ErrorMessage)
 	}
@@ -494,16 +712,21 @@ func (c *Client) Flush(ctx context.Context) error {
 		return fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.FlushRequest{}
-	
-	resp, err := c.client.Flush(reqCtx, req)
+
+	var resp *pb.FlushResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Flush(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return fmt.Errorf("flush operation failed: %w", err)
 	}
-	
+
 	if !resp.Success {
 		return fmt.Errorf("flush operation failed: %s", resp.ErrorMessage)
 	}
@@ -511,18 +734,25 @@ func (c *Client) Flush(ctx context.Context) error {
 	return nil
 }
 
-// Compact 压缩数据
-func (c *Client) Compact(ctx context.Context) error {
+// Compact 压缩数据，丢弃 revision 之前的历史版本；revision 为 0 时压缩到当前最新 revision。
+// Compact 之后，从已被丢弃的 revision 开始的 Subscribe 会通过 ErrorHandler 收到 ErrCompacted；
+// GetAtSnapshot 不做这种转换，返回的是包装后的服务端错误。
+func (c *Client) Compact(ctx context.Context, revision int64) error {
 	if !c.IsConnected() {
 		return fmt.Errorf("client not connected")
 	}
-	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
-	
-	req := &pb.CompactRequest{}
-	
-	resp, err := c.client.Compact(reqCtx, req)
+
+	req := &pb.CompactRequest{Revision: revision}
+
+	var resp *pb.CompactResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Compact(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return fmt.Errorf("compact operation failed: %w", err)
 	}
@@ -540,16 +770,21 @@ func (c *Client) GetStats(ctx context.Context) (*DatabaseStats, error) {
 		return nil, fmt.Errorf("client not connected")
 	}
 	
-	reqCtx, cancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
 	defer cancel()
 	
 	req := &pb.GetStatsRequest{}
-	
-	resp, err := c.client.GetStats(reqCtx, req)
+
+	var resp *pb.GetStatsResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.GetStats(rctx, req)
+		return callErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("get stats operation failed: %w", err)
 	}
-	
+
 	return &DatabaseStats{
 		MemtableSize:    resp.MemtableSize,
 		WALSize:         resp.WalSize,
@@ -558,66 +793,137 @@ func (c *Client) GetStats(ctx context.Context) (*DatabaseStats, error) {
 	}, nil
 }
 
-// Subscribe 订阅键变化事件
-func (c *Client) Subscribe(ctx context.Context, pattern string, callback SubscriptionCallback, includeDeletes bool) (*Subscription, error) {
+// Subscribe 订阅键变化事件。订阅围绕单调递增的 per-key revision 构建：服务端先从
+// opts.StartRevision（或 opts.ReplayFromOldest 时的最早可用 revision）开始重放历史事件，
+// 再切换到实时推送；连接中断时会使用 Subscription.LastRevision() 自动重连并续传，
+// 只有遇到不可恢复的错误（例如落后太多触发 ErrCompacted）才会调用 opts.ErrorHandler。
+func (c *Client) Subscribe(ctx context.Context, opts *SubscriptionOptions, callback SubscriptionCallback) (*Subscription, error) {
 	if !c.IsConnected() {
 		return nil, fmt.Errorf("client not connected")
 	}
-	
-	subCtx, cancel := context.WithCancel(ctx)
-	
-	req := &pb.SubscribeRequest{
-		KeyPattern:     pattern,
-		IncludeDeletes: includeDeletes,
+	if opts == nil {
+		opts = &SubscriptionOptions{}
 	}
-	
-	stream, err := c.client.Subscribe(subCtx, req)
-	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("subscribe operation failed: %w", err)
-	}
-	
+
+	subCtx, cancel := context.WithCancel(ctx)
 	subID := atomic.AddInt32(&c.subscriptionID, 1)
-	
+
 	subscription := &Subscription{
 		id:     subID,
 		client: c,
 		cancel: cancel,
 		active: 1,
 	}
-	
+	if opts.StartRevision > 0 {
+		atomic.StoreInt64(&subscription.lastRevision, opts.StartRevision-1)
+	}
+
 	c.subscriptionsMux.Lock()
 	c.subscriptions[subID] = subscription
 	c.subscriptionsMux.Unlock()
-	
-	// 启动接收goroutine
-	go func() {
-		defer func() {
-			subscription.Cancel()
-		}()
-		
-		for {
-			resp, err := stream.Recv()
-			if err != nil {
-				if err != io.EOF && subscription.IsActive() {
-					// 可以添加错误处理逻辑
-				}
+
+	go c.runSubscription(subCtx, subscription, opts, callback)
+
+	return subscription, nil
+}
+
+// runSubscription 驱动单个订阅的生命周期：建流、接收事件、在可恢复错误上自动重连
+func (c *Client) runSubscription(ctx context.Context, sub *Subscription, opts *SubscriptionOptions, callback SubscriptionCallback) {
+	defer sub.Cancel()
+
+	startRevision := opts.StartRevision
+	if opts.ReplayFromOldest {
+		startRevision = 0
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		rpcClient, err := c.rpc()
+		if err != nil {
+			if !c.sleepBeforeRetry(ctx) {
 				return
 			}
-			
-			if subscription.IsActive() && callback != nil {
-				event := &SubscriptionEvent{
-					Key:       resp.Key,
-					Value:     resp.Value,
-					Operation: resp.Operation,
-					Timestamp: resp.Timestamp,
-				}
-				callback(event)
+			continue
+		}
+
+		req := &pb.SubscribeRequest{
+			KeyPattern:     opts.Pattern,
+			IncludeDeletes: opts.IncludeDeletes,
+			StartRevision:  startRevision,
+		}
+
+		stream, err := rpcClient.Subscribe(c.withAuth(ctx), req)
+		if err != nil {
+			if !c.sleepBeforeRetry(ctx) {
+				return
 			}
+			continue
 		}
-	}()
-	
-	return subscription, nil
+
+		startRevision, err = c.drainSubscription(sub, stream, startRevision, callback)
+
+		if err == nil {
+			return // 服务端正常关闭了流
+		}
+		if errors.Is(err, ErrCompacted) {
+			if opts.ErrorHandler != nil {
+				opts.ErrorHandler(ErrCompacted)
+			}
+			return
+		}
+		if !c.sleepBeforeRetry(ctx) {
+			return
+		}
+	}
+}
+
+// drainSubscription 持续读取一个已建立的订阅流，直到遇到错误或流正常结束。返回值是重连时
+// 应当使用的下一个 StartRevision（StartRevision 语义为闭区间——服务端从该 revision 本身开始
+// 重放）：仅在事件被实际交付给 callback 之后才推进到 revision+1；如果这次连接上一个事件都没收到
+// 就断开了，返回的 revision 与传入的 startRevision 相同，从而避免在重连时跳过尚未观测到的事件。
+func (c *Client) drainSubscription(sub *Subscription, stream pb.KVDBService_SubscribeClient, startRevision int64, callback SubscriptionCallback) (int64, error) {
+	nextRevision := startRevision
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nextRevision, nil
+		}
+		if err != nil {
+			if isCompactedErr(err) {
+				return nextRevision, ErrCompacted
+			}
+			return nextRevision, err
+		}
+
+		atomic.StoreInt64(&sub.lastRevision, resp.Revision)
+		nextRevision = resp.Revision + 1
+
+		if sub.IsActive() && callback != nil {
+			callback(&SubscriptionEvent{
+				Key:       resp.Key,
+				Value:     resp.Value,
+				Operation: resp.Operation,
+				Timestamp: resp.Timestamp,
+				Revision:  resp.Revision,
+			})
+		}
+	}
+}
+
+// isCompactedErr 判断服务端是否因为请求的 revision 已被 Compact 清理而拒绝了订阅/重放请求
+func isCompactedErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	return st.Code() == codes.OutOfRange || strings.Contains(st.Message(), "compacted")
 }
 
 // removeSubscription 移除订阅
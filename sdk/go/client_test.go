@@ -0,0 +1,51 @@
+package kvdb
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsCompactedErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "out of range code",
+			err:  status.Error(codes.OutOfRange, "requested revision no longer available"),
+			want: true,
+		},
+		{
+			name: "message mentions compacted with unrelated code",
+			err:  status.Error(codes.FailedPrecondition, "revision has been compacted"),
+			want: true,
+		},
+		{
+			name: "unrelated error",
+			err:  status.Error(codes.Unavailable, "connection reset"),
+			want: false,
+		},
+		{
+			name: "non-gRPC error",
+			err:  errors.New("compacted"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCompactedErr(tt.err); got != tt.want {
+				t.Fatalf("isCompactedErr(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package kvdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/kvdb/proto"
+)
+
+// ClusterConfig 描述一个基于 Raft 复制的多副本集群
+type ClusterConfig struct {
+	// Peers 集群中所有节点的地址，Connect 会在其中发现当前 leader
+	Peers []string `json:"peers"`
+
+	ElectionTimeout   time.Duration `json:"election_timeout"`
+	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
+
+	// SnapshotThreshold WAL 超过该大小（字节）后触发快照与日志截断
+	SnapshotThreshold uint64 `json:"snapshot_threshold"`
+}
+
+// DefaultClusterConfig 返回默认集群配置
+func DefaultClusterConfig() *ClusterConfig {
+	return &ClusterConfig{
+		ElectionTimeout:   1 * time.Second,
+		HeartbeatInterval: 100 * time.Millisecond,
+		SnapshotThreshold: 64 * 1024 * 1024, // 64MB
+	}
+}
+
+// ErrNotLeader 表示被访问的节点不是当前 Raft leader
+var ErrNotLeader = errors.New("kvdb: not the raft leader")
+
+// ErrLeaderChanged 表示 leader 在请求处理过程中发生了变化
+var ErrLeaderChanged = errors.New("kvdb: leader changed")
+
+// discoverLeader 依次尝试 config.Cluster.Peers，将第一个愿意接受写请求的节点作为 leader 连接
+func (c *Client) discoverLeader(ctx context.Context) error {
+	if c.config.Cluster == nil || len(c.config.Cluster.Peers) == 0 {
+		return fmt.Errorf("kvdb: cluster mode requires at least one peer")
+	}
+
+	var lastErr error
+	for _, peer := range c.config.Cluster.Peers {
+		dialCtx, cancel := context.WithTimeout(ctx, c.config.ConnectionTimeout)
+		conn, err := grpc.DialContext(dialCtx, peer,
+			grpc.WithTransportCredentials(c.config.transportCredentials()),
+			grpc.WithDefaultCallOptions(
+				grpc.MaxCallRecvMsgSize(c.config.MaxRecvMsgSize),
+				grpc.MaxCallSendMsgSize(c.config.MaxSendMsgSize),
+			),
+		)
+		cancel()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		client := pb.NewKVDBServiceClient(conn)
+		statCtx, statCancel := context.WithTimeout(ctx, c.config.RequestTimeout)
+		_, err = client.GetStats(statCtx, &pb.GetStatsRequest{})
+		statCancel()
+		if isNotLeaderErr(err) {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			conn.Close()
+			lastErr = err
+			continue
+		}
+
+		c.connMu.Lock()
+		oldConn := c.conn
+		c.conn = conn
+		c.client = client
+		c.leaderAddr = peer
+		c.connMu.Unlock()
+
+		if oldConn != nil {
+			// 不立即关闭旧连接：可能仍有在旧 leader 发生变化之前发起、尚未返回的 RPC 正在使用它，
+			// 立即 Close 会让那些请求以连接错误失败而不是超时。延迟到 RequestTimeout 之后再关闭，
+			// 足够覆盖任何仍在飞行中的请求的生命周期。
+			time.AfterFunc(c.config.RequestTimeout, func() { oldConn.Close() })
+		}
+		return nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("kvdb: no reachable peer")
+	}
+	return fmt.Errorf("kvdb: failed to discover leader: %w", lastErr)
+}
+
+// withLeaderRetry 执行一次 RPC 调用；当目标节点返回 ErrNotLeader/ErrLeaderChanged 时，
+// 重新发现当前 leader 并在剩余的 MaxRetries 次数内重试
+func (c *Client) withLeaderRetry(ctx context.Context, call func(ctx context.Context) error) error {
+	if c.config.Cluster == nil {
+		return call(ctx)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		err := call(ctx)
+		if err == nil {
+			return nil
+		}
+		if !isNotLeaderErr(err) {
+			return err
+		}
+
+		lastErr = err
+		if discErr := c.discoverLeader(ctx); discErr != nil {
+			return fmt.Errorf("leader discovery failed after %v: %w", err, discErr)
+		}
+	}
+
+	return lastErr
+}
+
+func isNotLeaderErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	if st.Code() != codes.FailedPrecondition {
+		return false
+	}
+	msg := st.Message()
+	return strings.Contains(msg, "not leader") || strings.Contains(msg, "leader changed")
+}
+
+// LinearizableGet 执行线性一致读：在返回前等待 leader 的已提交索引追上请求发起时的 commit index。
+// 相较于 Get（lease read，延迟更低但依赖 leader 的有界时钟租约假设），LinearizableGet 牺牲部分延迟换取
+// 强一致性保证，适合需要"读到自己刚写入的值"的场景。
+func (c *Client) LinearizableGet(ctx context.Context, key string) (string, bool, error) {
+	if !c.IsConnected() {
+		return "", false, fmt.Errorf("client not connected")
+	}
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	req := &pb.GetRequest{Key: key, Linearizable: true}
+
+	var resp *pb.GetResponse
+	err := c.withLeaderRetry(reqCtx, func(rctx context.Context) error {
+		var callErr error
+		resp, callErr = c.currentClient().Get(rctx, req)
+		return callErr
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("linearizable get operation failed: %w", err)
+	}
+
+	if resp.ErrorMessage != "" {
+		return "", false, fmt.Errorf("linearizable get operation failed: %s", resp.ErrorMessage)
+	}
+
+	return resp.Value, resp.Found, nil
+}
@@ -0,0 +1,56 @@
+package kvdb
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsNotLeaderErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "not leader message",
+			err:  status.Error(codes.FailedPrecondition, "node is not leader"),
+			want: true,
+		},
+		{
+			name: "leader changed message",
+			err:  status.Error(codes.FailedPrecondition, "leader changed during request"),
+			want: true,
+		},
+		{
+			name: "failed precondition with unrelated message",
+			err:  status.Error(codes.FailedPrecondition, "key already locked"),
+			want: false,
+		},
+		{
+			name: "different code with matching message",
+			err:  status.Error(codes.Unavailable, "not leader"),
+			want: false,
+		},
+		{
+			name: "non-gRPC error",
+			err:  errors.New("not leader"),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNotLeaderErr(tt.err); got != tt.want {
+				t.Fatalf("isNotLeaderErr(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
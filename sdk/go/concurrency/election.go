@@ -0,0 +1,118 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	kvdb "github.com/kvdb/go-client"
+)
+
+// Election 在 prefix 下实现 leader 选举：每个候选者注册一个以自身租约命名的键，
+// 字典序最小的键对应当前 leader，落选者通过 Observe 观察 leader 变化
+type Election struct {
+	session *Session
+	prefix  string
+
+	myKey string
+}
+
+// NewElection 创建一个作用于给定 prefix 的选举
+func NewElection(session *Session, prefix string) *Election {
+	return &Election{session: session, prefix: strings.TrimRight(prefix, "/") + "/"}
+}
+
+// Campaign 注册候选资格并阻塞直至当选 leader 或 ctx 被取消；val 是当选后通过 Leader/Observe
+// 可见的候选者标识（例如主机名）
+func (e *Election) Campaign(ctx context.Context, val string) error {
+	client := e.session.Client()
+	leaseID := e.session.Lease().ID
+	myKey := fmt.Sprintf("%s%016x", e.prefix, leaseID)
+
+	if err := client.PutWithLease(ctx, myKey, val, leaseID); err != nil {
+		return fmt.Errorf("concurrency: failed to register candidacy: %w", err)
+	}
+	e.myKey = myKey
+
+	for {
+		keys, err := client.PrefixScan(ctx, e.prefix, 0)
+		if err != nil {
+			return fmt.Errorf("concurrency: failed to scan election prefix: %w", err)
+		}
+
+		predecessor, ok := predecessorOf(keys, myKey)
+		if !ok {
+			return nil
+		}
+
+		if err := waitForDelete(ctx, client, predecessor); err != nil {
+			return err
+		}
+	}
+}
+
+// Leader 返回当前 leader 注册时提交的值
+func (e *Election) Leader(ctx context.Context) (string, error) {
+	keys, err := e.session.Client().PrefixScan(ctx, e.prefix, 0)
+	if err != nil {
+		return "", fmt.Errorf("concurrency: failed to scan election prefix: %w", err)
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("concurrency: no leader elected")
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	return keys[0].Value, nil
+}
+
+// Observe 返回一个 channel，prefix 下的键每次变化都推送当前 leader 的值（leader 未变时也会推送），
+// channel 只保留最新的一个值，读取方未及时取走的旧值会被替换；ctx 被取消时订阅会被
+// 取消，但 channel 本身不会被关闭——订阅回调与取消可能并发执行，关闭一个仍可能被写入的 channel
+// 会 panic，调用方应当以 ctx 被取消作为停止读取该 channel 的信号，而不是依赖它被 close
+func (e *Election) Observe(ctx context.Context) (<-chan string, error) {
+	client := e.session.Client()
+	ch := make(chan string, 1)
+
+	if leader, err := e.Leader(ctx); err == nil {
+		select {
+		case ch <- leader:
+		default:
+		}
+	}
+
+	subOpts := &kvdb.SubscriptionOptions{Pattern: e.prefix + "*", IncludeDeletes: true}
+	sub, err := client.Subscribe(ctx, subOpts, func(_ *kvdb.SubscriptionEvent) {
+		if leader, err := e.Leader(ctx); err == nil {
+			// 替换读取方尚未取走的旧值；回调只在单个 goroutine 中执行，清空后的发送不会阻塞
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- leader
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("concurrency: failed to observe election: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		sub.Cancel()
+	}()
+
+	return ch, nil
+}
+
+// Resign 放弃 leader 身份
+func (e *Election) Resign(ctx context.Context) error {
+	if e.myKey == "" {
+		return nil
+	}
+
+	if err := e.session.Client().Delete(ctx, e.myKey); err != nil {
+		return fmt.Errorf("concurrency: failed to resign: %w", err)
+	}
+	e.myKey = ""
+	return nil
+}
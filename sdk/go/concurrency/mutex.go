@@ -0,0 +1,62 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Mutex 是建立在 Session 租约之上的跨进程互斥锁：持有者在 prefix 下创建一个以自身租约 ID
+// 命名的键，当该键是 prefix 下字典序最小的键时即视为持有锁，否则等待排在前面的键被删除
+type Mutex struct {
+	session *Session
+	prefix  string
+
+	myKey string
+}
+
+// NewMutex 创建一个作用于给定 prefix 的互斥锁；同一 prefix 下的所有 Mutex 互斥
+func NewMutex(session *Session, prefix string) *Mutex {
+	return &Mutex{session: session, prefix: strings.TrimRight(prefix, "/") + "/"}
+}
+
+// Lock 获取锁，阻塞直至持有锁或 ctx 被取消
+func (m *Mutex) Lock(ctx context.Context) error {
+	client := m.session.Client()
+	leaseID := m.session.Lease().ID
+	myKey := fmt.Sprintf("%s%016x", m.prefix, leaseID)
+
+	if err := client.PutWithLease(ctx, myKey, "", leaseID); err != nil {
+		return fmt.Errorf("concurrency: failed to create lock key: %w", err)
+	}
+	m.myKey = myKey
+
+	for {
+		keys, err := client.PrefixScan(ctx, m.prefix, 0)
+		if err != nil {
+			return fmt.Errorf("concurrency: failed to scan lock prefix: %w", err)
+		}
+
+		predecessor, ok := predecessorOf(keys, myKey)
+		if !ok {
+			return nil
+		}
+
+		if err := waitForDelete(ctx, client, predecessor); err != nil {
+			return err
+		}
+	}
+}
+
+// Unlock 释放锁
+func (m *Mutex) Unlock(ctx context.Context) error {
+	if m.myKey == "" {
+		return nil
+	}
+
+	if err := m.session.Client().Delete(ctx, m.myKey); err != nil {
+		return fmt.Errorf("concurrency: failed to release lock: %w", err)
+	}
+	m.myKey = ""
+	return nil
+}
@@ -0,0 +1,92 @@
+// Package concurrency 基于 kvdb 的租约和事务提供分布式锁与 leader 选举，用法参考 etcd 的
+// clientv3/concurrency 包：一个 Session 绑定一个自动续约的租约，Mutex 和 Election 都构建于其上。
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	kvdb "github.com/kvdb/go-client"
+)
+
+const defaultSessionTTL = 60 * time.Second
+
+// Session 将一个租约与其后台自动续约绑定在一起
+type Session struct {
+	client *kvdb.Client
+	lease  *kvdb.Lease
+
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// SessionOption 配置 NewSession 的行为
+type SessionOption func(*sessionConfig)
+
+type sessionConfig struct {
+	ttl time.Duration
+}
+
+// WithTTL 设置会话底层租约的 TTL，默认为 60 秒
+func WithTTL(ttl time.Duration) SessionOption {
+	return func(c *sessionConfig) { c.ttl = ttl }
+}
+
+// NewSession 申请一个新租约并启动后台自动续约；Session.Close 或租约到期都会使所有绑定在
+// 该租约上的 Mutex/Election 键被服务端自动删除
+func NewSession(ctx context.Context, client *kvdb.Client, opts ...SessionOption) (*Session, error) {
+	cfg := &sessionConfig{ttl: defaultSessionTTL}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	lease, err := client.Grant(ctx, cfg.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("concurrency: failed to grant session lease: %w", err)
+	}
+
+	keepAliveCtx, cancel := context.WithCancel(context.Background())
+	ch, err := client.KeepAlive(keepAliveCtx, lease.ID)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("concurrency: failed to start keepalive: %w", err)
+	}
+
+	s := &Session{client: client, lease: lease, cancel: cancel}
+
+	go func() {
+		for range ch {
+			// 消费心跳确认以驱动续约；KeepAlive 的重连逻辑已经在 client 内部处理
+		}
+	}()
+
+	return s, nil
+}
+
+// Lease 返回会话底层的租约
+func (s *Session) Lease() *kvdb.Lease {
+	return s.lease
+}
+
+// Client 返回会话使用的客户端
+func (s *Session) Client() *kvdb.Client {
+	return s.client
+}
+
+// Close 停止续约并撤销底层租约
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.cancel()
+	return s.client.Revoke(context.Background(), s.lease.ID)
+}
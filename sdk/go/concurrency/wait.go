@@ -0,0 +1,66 @@
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	kvdb "github.com/kvdb/go-client"
+)
+
+// predecessorOf 在按 Key 字典序排序的 keys 中找到排在 myKey 之前的键；myKey 本身排第一个时
+// 返回 ok=false，表示调用方已经持有锁/已经当选
+func predecessorOf(keys []kvdb.KeyValue, myKey string) (string, bool) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+	for i, kv := range keys {
+		if kv.Key == myKey {
+			if i == 0 {
+				return "", false
+			}
+			return keys[i-1].Key, true
+		}
+	}
+	return "", false
+}
+
+// waitForDelete 阻塞直至 key 被删除或 ctx 被取消；用于在排队等锁/等选举时等待前一个持有者释放
+func waitForDelete(ctx context.Context, client *kvdb.Client, key string) error {
+	_, found, err := client.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("concurrency: failed to check predecessor key: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	deleted := make(chan struct{}, 1)
+	subOpts := &kvdb.SubscriptionOptions{Pattern: key, IncludeDeletes: true}
+	sub, err := client.Subscribe(ctx, subOpts, func(event *kvdb.SubscriptionEvent) {
+		if event.Key == key && event.Operation == "delete" {
+			select {
+			case deleted <- struct{}{}:
+			default:
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("concurrency: failed to watch predecessor key: %w", err)
+	}
+	defer sub.Cancel()
+
+	// 订阅建立之后重新确认一次，避免在 Get 和 Subscribe 之间发生的删除被错过
+	_, found, err = client.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("concurrency: failed to re-check predecessor key: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-deleted:
+		return nil
+	}
+}
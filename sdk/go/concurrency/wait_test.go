@@ -0,0 +1,57 @@
+package concurrency
+
+import (
+	"testing"
+
+	kvdb "github.com/kvdb/go-client"
+)
+
+func TestPredecessorOf(t *testing.T) {
+	keys := []kvdb.KeyValue{
+		{Key: "election/0000000000000003"},
+		{Key: "election/0000000000000001"},
+		{Key: "election/0000000000000002"},
+	}
+
+	tests := []struct {
+		name      string
+		myKey     string
+		wantKey   string
+		wantFound bool
+	}{
+		{
+			name:      "first in order has no predecessor",
+			myKey:     "election/0000000000000001",
+			wantFound: false,
+		},
+		{
+			name:      "middle key returns its immediate predecessor",
+			myKey:     "election/0000000000000002",
+			wantKey:   "election/0000000000000001",
+			wantFound: true,
+		},
+		{
+			name:      "last key returns its immediate predecessor",
+			myKey:     "election/0000000000000003",
+			wantKey:   "election/0000000000000002",
+			wantFound: true,
+		},
+		{
+			name:      "key not present in the set",
+			myKey:     "election/0000000000000099",
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := predecessorOf(keys, tt.myKey)
+			if ok != tt.wantFound {
+				t.Fatalf("predecessorOf() ok = %v, want %v", ok, tt.wantFound)
+			}
+			if ok && got != tt.wantKey {
+				t.Fatalf("predecessorOf() = %q, want %q", got, tt.wantKey)
+			}
+		})
+	}
+}
@@ -176,7 +176,7 @@ func snapshotOperationsExample(ctx context.Context, client *kvdb.Client) error {
 	fmt.Printf("Created snapshot: %d\n", snapshot.ID)
 	
 	// 在快照上读取数据
-	value, found, err := client.GetAtSnapshot(ctx, "user:1001", snapshot)
+	value, found, err := client.GetAtSnapshot(ctx, "user:1001", snapshot.Revision)
 	if err != nil {
 		return fmt.Errorf("get at snapshot failed: %w", err)
 	}
@@ -201,7 +201,7 @@ func snapshotOperationsExample(ctx context.Context, client *kvdb.Client) error {
 	}
 	
 	// 快照读取（应该是旧值）
-	snapshotValue, found, err := client.GetAtSnapshot(ctx, "user:1001", snapshot)
+	snapshotValue, found, err := client.GetAtSnapshot(ctx, "user:1001", snapshot.Revision)
 	if err != nil {
 		return fmt.Errorf("snapshot GET failed: %w", err)
 	}
@@ -222,10 +222,17 @@ func subscriptionExample(ctx context.Context, client *kvdb.Client) error {
 	fmt.Println("\n=== Subscription Example ===")
 	
 	// 开始订阅
-	subscription, err := client.Subscribe(ctx, "user:*", func(event *kvdb.SubscriptionEvent) {
+	subOpts := &kvdb.SubscriptionOptions{
+		Pattern:        "user:*",
+		IncludeDeletes: true,
+		ErrorHandler: func(err error) {
+			fmt.Printf("Subscription error: %v\n", err)
+		},
+	}
+	subscription, err := client.Subscribe(ctx, subOpts, func(event *kvdb.SubscriptionEvent) {
 		fmt.Printf("Subscription event: %s %s = %s\n", event.Operation, event.Key, event.Value)
-	}, true)
-	
+	})
+
 	if err != nil {
 		return fmt.Errorf("subscribe failed: %w", err)
 	}
@@ -272,7 +279,7 @@ func managementOperationsExample(ctx context.Context, client *kvdb.Client) error
 	fmt.Println("Flushed data to disk")
 	
 	// 压缩数据
-	if err := client.Compact(ctx); err != nil {
+	if err := client.Compact(ctx, 0); err != nil {
 		return fmt.Errorf("compact failed: %w", err)
 	}
 	fmt.Println("Compacted data")
@@ -0,0 +1,273 @@
+package kvdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/kvdb/proto"
+)
+
+// Lease 表示一个服务端租约，绑定到该租约的键会在租约过期时被自动删除
+type Lease struct {
+	ID  uint64
+	TTL time.Duration
+}
+
+// LeaseKeepAliveResponse KeepAlive流上收到的一次续约确认
+type LeaseKeepAliveResponse struct {
+	ID  uint64
+	TTL time.Duration
+}
+
+// Grant 创建一个存活时间为 ttl 的新租约；ttl 必须为正，不足 1 秒的部分会被向上取整为 1 秒，
+// 因为底层协议以整数秒表示 TTL，直接截断会产生一个 0 秒即过期的租约
+func (c *Client) Grant(ctx context.Context, ttl time.Duration) (*Lease, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("kvdb: lease ttl must be positive")
+	}
+
+	ttlSeconds := int64(ttl / time.Second)
+	if ttl%time.Second != 0 {
+		ttlSeconds++
+	}
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	req := &pb.LeaseGrantRequest{TtlSeconds: ttlSeconds}
+
+	var resp *pb.LeaseGrantResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.LeaseGrant(rctx, req)
+		return callErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lease grant operation failed: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return nil, fmt.Errorf("lease grant operation failed: %s", resp.ErrorMessage)
+	}
+
+	return &Lease{ID: resp.LeaseId, TTL: time.Duration(resp.TtlSeconds) * time.Second}, nil
+}
+
+// Revoke 撤销一个租约，绑定到该租约的所有键会被立即删除
+func (c *Client) Revoke(ctx context.Context, id uint64) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	req := &pb.LeaseRevokeRequest{LeaseId: id}
+
+	var resp *pb.LeaseRevokeResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.LeaseRevoke(rctx, req)
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("lease revoke operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("lease revoke operation failed: %s", resp.ErrorMessage)
+	}
+
+	return nil
+}
+
+// TimeToLive 查询租约的剩余存活时间以及当前绑定到该租约的键
+func (c *Client) TimeToLive(ctx context.Context, id uint64) (time.Duration, []string, error) {
+	if !c.IsConnected() {
+		return 0, nil, fmt.Errorf("client not connected")
+	}
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	req := &pb.LeaseTimeToLiveRequest{LeaseId: id, Keys: true}
+
+	var resp *pb.LeaseTimeToLiveResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.LeaseTimeToLive(rctx, req)
+		return callErr
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("lease time-to-live operation failed: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return 0, nil, fmt.Errorf("lease time-to-live operation failed: %s", resp.ErrorMessage)
+	}
+
+	return time.Duration(resp.RemainingSeconds) * time.Second, resp.Keys, nil
+}
+
+// PutWithLease 存储键值对并将其生命周期绑定到指定租约；租约过期或被撤销时该键会被自动删除
+func (c *Client) PutWithLease(ctx context.Context, key, value string, leaseID uint64) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("client not connected")
+	}
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	req := &pb.PutRequest{
+		Key:     key,
+		Value:   value,
+		LeaseId: leaseID,
+	}
+
+	var resp *pb.PutResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Put(rctx, req)
+		return callErr
+	})
+	if err != nil {
+		return fmt.Errorf("put with lease operation failed: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("put with lease operation failed: %s", resp.ErrorMessage)
+	}
+
+	return nil
+}
+
+// leaseKeepAlive 跟踪单个租约的自动续约状态，用于在重连后重新建立 KeepAlive 流
+type leaseKeepAlive struct {
+	id     uint64
+	ttl    time.Duration
+	cancel context.CancelFunc
+}
+
+// KeepAlive 打开一个双向流，按 TTL/3 的间隔发送续约心跳，并在连接中断后使用最近一次观测到的租约
+// 自动重新建立流；调用方通过取消传入的 ctx 来停止续约
+func (c *Client) KeepAlive(ctx context.Context, id uint64) (<-chan *LeaseKeepAliveResponse, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	kaCtx, cancel := context.WithCancel(ctx)
+	ch := make(chan *LeaseKeepAliveResponse, 1)
+
+	ka := &leaseKeepAlive{id: id, cancel: cancel}
+	c.leaseKeepAlivesMux.Lock()
+	c.leaseKeepAlives[id] = ka
+	c.leaseKeepAlivesMux.Unlock()
+
+	go c.runKeepAlive(kaCtx, ka, ch)
+
+	return ch, nil
+}
+
+// runKeepAlive 驱动单个租约的 KeepAlive 流，断线后重新打开流并继续发送心跳
+func (c *Client) runKeepAlive(ctx context.Context, ka *leaseKeepAlive, ch chan<- *LeaseKeepAliveResponse) {
+	defer close(ch)
+	defer func() {
+		c.leaseKeepAlivesMux.Lock()
+		delete(c.leaseKeepAlives, ka.id)
+		c.leaseKeepAlivesMux.Unlock()
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		rpcClient, err := c.rpc()
+		if err != nil {
+			if !c.sleepBeforeRetry(ctx) {
+				return
+			}
+			continue
+		}
+
+		stream, err := rpcClient.LeaseKeepAlive(c.withAuth(ctx))
+		if err != nil {
+			if !c.sleepBeforeRetry(ctx) {
+				return
+			}
+			continue
+		}
+
+		if !c.keepAliveLoop(ctx, ka, stream, ch) {
+			return
+		}
+
+		if !c.sleepBeforeRetry(ctx) {
+			return
+		}
+	}
+}
+
+// keepAliveInterval 返回给定 TTL 下的心跳发送间隔：以 TTL/3 为目标，ttl 未知（首次建流，
+// 尚未收到服务端确认的真实 TTL）或过短时退化为 1s，避免 ticker 间隔为 0 或负数
+func keepAliveInterval(ttl time.Duration) time.Duration {
+	interval := ttl / 3
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return interval
+}
+
+// keepAliveLoop 在已建立的流上发送心跳并转发响应，返回 false 表示应当停止整个 KeepAlive
+func (c *Client) keepAliveLoop(ctx context.Context, ka *leaseKeepAlive, stream pb.KVDBService_LeaseKeepAliveClient, ch chan<- *LeaseKeepAliveResponse) bool {
+	ticker := time.NewTicker(keepAliveInterval(ka.ttl))
+	defer ticker.Stop()
+
+	respCh := make(chan *pb.LeaseKeepAliveResponse, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			respCh <- resp
+		}
+	}()
+
+	if err := stream.Send(&pb.LeaseKeepAliveRequest{LeaseId: ka.id}); err != nil {
+		return true
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case err := <-errCh:
+			_ = err
+			return true
+		case resp := <-respCh:
+			ka.ttl = time.Duration(resp.TtlSeconds) * time.Second
+			ticker.Reset(keepAliveInterval(ka.ttl))
+			select {
+			case ch <- &LeaseKeepAliveResponse{ID: resp.LeaseId, TTL: ka.ttl}:
+			default:
+			}
+		case <-ticker.C:
+			if err := stream.Send(&pb.LeaseKeepAliveRequest{LeaseId: ka.id}); err != nil {
+				return true
+			}
+		}
+	}
+}
+
+// sleepBeforeRetry 在重连前等待一小段时间，ctx 取消时立即返回 false
+func (c *Client) sleepBeforeRetry(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(500 * time.Millisecond):
+		return true
+	}
+}
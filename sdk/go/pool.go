@@ -0,0 +1,355 @@
+package kvdb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/kvdb/proto"
+)
+
+// poolConn 是连接池中的一个成员：一条到某个 endpoint 的 gRPC 连接及其健康状态
+type poolConn struct {
+	endpoint string
+	conn     *grpc.ClientConn
+	client   pb.KVDBServiceClient
+
+	healthy  int32 // atomic: 1=healthy, 0=unhealthy
+	lastUsed int64 // atomic: UnixNano，最近一次被选中使用的时间
+}
+
+// connPool 维护到多个 endpoint 的一组warm连接，供 Client 在其上做轮询负载均衡与故障转移
+type connPool struct {
+	config *ClientConfig
+
+	// authFunc 为健康检查等后台发起的调用附加认证信息，与 Client.withAuth 语义一致
+	authFunc func(context.Context) context.Context
+
+	mu      sync.RWMutex
+	conns   []*poolConn
+	nextIdx uint64
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newConnPool 创建一个空连接池，调用方需要调用 start 建立初始连接并启动后台维护 goroutine
+func newConnPool(config *ClientConfig, authFunc func(context.Context) context.Context) *connPool {
+	if authFunc == nil {
+		authFunc = func(ctx context.Context) context.Context { return ctx }
+	}
+	return &connPool{config: config, authFunc: authFunc, stopCh: make(chan struct{})}
+}
+
+// start 连接到 endpoints，尝试建立 MinConnections 条连接（不超过 MaxConnections，轮流取自
+// endpoints），只要有一条成功即返回，并启动后台健康检查与空闲连接回收
+func (p *connPool) start(ctx context.Context, endpoints []string) error {
+	if len(endpoints) == 0 {
+		return fmt.Errorf("kvdb: connection pool requires at least one endpoint")
+	}
+
+	want := p.config.MinConnections
+	if want <= 0 {
+		want = 1
+	}
+	if want > p.config.MaxConnections {
+		want = p.config.MaxConnections
+	}
+
+	var lastErr error
+	for i := 0; i < want; i++ {
+		endpoint := endpoints[i%len(endpoints)]
+		pc, err := p.dial(ctx, endpoint)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		p.mu.Lock()
+		p.conns = append(p.conns, pc)
+		p.mu.Unlock()
+	}
+
+	if len(p.conns) == 0 {
+		return fmt.Errorf("kvdb: failed to establish any pooled connection: %w", lastErr)
+	}
+
+	p.wg.Add(2)
+	go p.healthCheckLoop()
+	go p.idleCloseLoop()
+
+	return nil
+}
+
+// dial 建立到单个 endpoint 的连接
+func (p *connPool) dial(ctx context.Context, endpoint string) (*poolConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(p.config.transportCredentials()),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(p.config.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(p.config.MaxSendMsgSize),
+		),
+	}
+	if p.config.EnableCompression {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor("gzip")))
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, p.config.ConnectionTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(dialCtx, endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", endpoint, err)
+	}
+
+	pc := &poolConn{endpoint: endpoint, conn: conn, client: pb.NewKVDBServiceClient(conn), healthy: 1}
+	atomic.StoreInt64(&pc.lastUsed, time.Now().UnixNano())
+	return pc, nil
+}
+
+// pick 以轮询方式选取一个健康的连接；所有连接都不健康时退化为随机选取一个连接，
+// 让调用方通过重试机制自然发现问题
+func (p *connPool) pick() (pb.KVDBServiceClient, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.conns) == 0 {
+		return nil, fmt.Errorf("kvdb: connection pool is empty")
+	}
+
+	n := len(p.conns)
+	start := int(atomic.AddUint64(&p.nextIdx, 1))
+	for i := 0; i < n; i++ {
+		pc := p.conns[(start+i)%n]
+		if atomic.LoadInt32(&pc.healthy) == 1 {
+			atomic.StoreInt64(&pc.lastUsed, time.Now().UnixNano())
+			return pc.client, nil
+		}
+	}
+
+	pc := p.conns[rand.Intn(n)]
+	atomic.StoreInt64(&pc.lastUsed, time.Now().UnixNano())
+	return pc.client, nil
+}
+
+// endpoints 返回当前连接池覆盖的 endpoint 列表
+func (p *connPool) endpoints() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make([]string, 0, len(p.conns))
+	seen := make(map[string]bool)
+	for _, pc := range p.conns {
+		if !seen[pc.endpoint] {
+			seen[pc.endpoint] = true
+			result = append(result, pc.endpoint)
+		}
+	}
+	return result
+}
+
+// setEndpoints 更新集群成员：为新增的 endpoint 建立连接，关闭不再出现在列表中的连接
+func (p *connPool) setEndpoints(ctx context.Context, endpoints []string) error {
+	want := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		want[e] = true
+	}
+
+	p.mu.Lock()
+	var kept []*poolConn
+	var removed []*poolConn
+	for _, pc := range p.conns {
+		if want[pc.endpoint] {
+			kept = append(kept, pc)
+			delete(want, pc.endpoint)
+		} else {
+			removed = append(removed, pc)
+		}
+	}
+	p.conns = kept
+	p.mu.Unlock()
+
+	for _, pc := range removed {
+		pc.conn.Close()
+	}
+
+	var lastErr error
+	for endpoint := range want {
+		pc, err := p.dial(ctx, endpoint)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		p.mu.Lock()
+		p.conns = append(p.conns, pc)
+		p.mu.Unlock()
+	}
+
+	return lastErr
+}
+
+// healthCheckLoop 周期性地对每条连接发起 GetStats 调用，更新其健康状态
+func (p *connPool) healthCheckLoop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.mu.RLock()
+			conns := append([]*poolConn(nil), p.conns...)
+			p.mu.RUnlock()
+
+			for _, pc := range conns {
+				ctx, cancel := context.WithTimeout(p.authFunc(context.Background()), p.config.RequestTimeout)
+				_, err := pc.client.GetStats(ctx, &pb.GetStatsRequest{})
+				cancel()
+
+				if err != nil && status.Code(err) != codes.OK {
+					atomic.StoreInt32(&pc.healthy, 0)
+				} else {
+					atomic.StoreInt32(&pc.healthy, 1)
+				}
+			}
+		}
+	}
+}
+
+// idleCloseLoop 周期性地关闭超过 ConnectionIdleTimeout 未被使用的连接，但始终保留至少 MinConnections 条
+func (p *connPool) idleCloseLoop() {
+	defer p.wg.Done()
+
+	if p.config.ConnectionIdleTimeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.config.ConnectionIdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.closeIdle()
+		}
+	}
+}
+
+func (p *connPool) closeIdle() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	minConns := p.config.MinConnections
+	if minConns <= 0 {
+		minConns = 1
+	}
+
+	now := time.Now()
+	active := len(p.conns)
+	var kept []*poolConn
+	for _, pc := range p.conns {
+		idleFor := now.Sub(time.Unix(0, atomic.LoadInt64(&pc.lastUsed)))
+		if idleFor > p.config.ConnectionIdleTimeout && active > minConns {
+			pc.conn.Close()
+			active--
+			continue
+		}
+		kept = append(kept, pc)
+	}
+	p.conns = kept
+}
+
+// close 关闭连接池中的全部连接并停止后台 goroutine
+func (p *connPool) close() error {
+	close(p.stopCh)
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var firstErr error
+	for _, pc := range p.conns {
+		if err := pc.conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	p.conns = nil
+	return firstErr
+}
+
+// isRetryableErr 判断一次 RPC 失败是否值得在另一个 endpoint 上重试：仅瞬时的
+// Unavailable/DeadlineExceeded 被认为是端点级故障，其余错误（例如业务校验失败）重试没有意义
+func isRetryableErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// backoffWithJitter 返回第 attempt 次重试前的等待时长：以 100ms 为基数指数退避，
+// 上限 2s，并叠加随机抖动以避免多个客户端同时对同一组 endpoint 发起重试风暴
+func backoffWithJitter(attempt int) time.Duration {
+	const base = 100 * time.Millisecond
+	const max = 2 * time.Second
+
+	d := base << uint(attempt)
+	if d <= 0 || d > max {
+		d = max
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// withRPCRetry 执行一次 RPC 调用。集群模式下委托 withLeaderRetry 处理 leader 漂移；
+// 其余模式下在 Unavailable/DeadlineExceeded 等瞬时错误上按指数退避 + 抖动重试最多
+// MaxRetries 次，每次重试都通过 c.rpc() 重新挑选连接，从而在某个 endpoint 故障时
+// 自动转移到池中的其他连接
+func (c *Client) withRPCRetry(ctx context.Context, call func(rctx context.Context, rpcClient pb.KVDBServiceClient) error) error {
+	if c.config.Cluster != nil {
+		return c.withLeaderRetry(ctx, func(rctx context.Context) error {
+			return call(rctx, c.currentClient())
+		})
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		rpcClient, err := c.rpc()
+		if err != nil {
+			return err
+		}
+
+		err = call(ctx, rpcClient)
+		if err == nil {
+			return nil
+		}
+		if !isRetryableErr(err) || attempt >= c.config.MaxRetries {
+			return err
+		}
+
+		lastErr = err
+		if !c.sleepBackoff(ctx, attempt) {
+			return lastErr
+		}
+	}
+}
+
+// sleepBackoff 在下一次重试前等待 backoffWithJitter(attempt)，ctx 取消时立即返回 false
+func (c *Client) sleepBackoff(ctx context.Context, attempt int) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(backoffWithJitter(attempt)):
+		return true
+	}
+}
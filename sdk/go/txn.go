@@ -0,0 +1,265 @@
+package kvdb
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/kvdb/proto"
+)
+
+// CompareTarget 指定 Compare 检查的字段
+type CompareTarget int32
+
+const (
+	// CompareValue 比较键当前的值
+	CompareValue CompareTarget = iota
+	// CompareVersion 比较键自创建以来被修改的次数
+	CompareVersion
+	// CompareModRevision 比较键最近一次被修改时的 revision
+	CompareModRevision
+	// CompareCreateRevision 比较键被创建时的 revision
+	CompareCreateRevision
+)
+
+// CompareResult 指定 Compare 使用的比较运算符
+type CompareResult int32
+
+const (
+	CompareEqual CompareResult = iota
+	CompareGreater
+	CompareLess
+	CompareNotEqual
+)
+
+// Compare 是 Txn 的 If 分支中的单个条件
+type Compare struct {
+	Key    string
+	Target CompareTarget
+	Result CompareResult
+
+	Value          string
+	Version        int64
+	ModRevision    int64
+	CreateRevision int64
+}
+
+// CompareValueEqual 生成一个"键 key 的值等于 value"的条件，是最常用的 CAS 条件
+func CompareValueEqual(key, value string) Compare {
+	return Compare{Key: key, Target: CompareValue, Result: CompareEqual, Value: value}
+}
+
+// CompareVersionEqual 生成一个"键 key 被修改次数等于 version"的条件
+func CompareVersionEqual(key string, version int64) Compare {
+	return Compare{Key: key, Target: CompareVersion, Result: CompareEqual, Version: version}
+}
+
+// CompareModRevisionEqual 生成一个"键 key 最近一次修改的 revision 等于 rev"的条件
+func CompareModRevisionEqual(key string, rev int64) Compare {
+	return Compare{Key: key, Target: CompareModRevision, Result: CompareEqual, ModRevision: rev}
+}
+
+// CompareCreateRevisionEqual 生成一个"键 key 创建时的 revision 等于 rev"的条件
+func CompareCreateRevisionEqual(key string, rev int64) Compare {
+	return Compare{Key: key, Target: CompareCreateRevision, Result: CompareEqual, CreateRevision: rev}
+}
+
+// OpType 标识 Txn 分支中单个操作的种类
+type OpType int32
+
+const (
+	OpTypePut OpType = iota
+	OpTypeGet
+	OpTypeDelete
+	OpTypeRange
+)
+
+// Op 是 Txn 的 Then/Else 分支中的单个操作
+type Op struct {
+	Type OpType
+
+	Key	string
+	Value	string
+
+	// RangeEnd 非空时 Key 被当作范围起点，用于 OpTypeRange 的范围读取和 OpTypeDelete 的范围删除
+	RangeEnd string
+}
+
+// OpPut 构造一个写入操作
+func OpPut(key, value string) Op {
+	return Op{Type: OpTypePut, Key: key, Value: value}
+}
+
+// OpGet 构造一个读取操作
+func OpGet(key string) Op {
+	return Op{Type: OpTypeGet, Key: key}
+}
+
+// OpDelete 构造一个删除操作
+func OpDelete(key string) Op {
+	return Op{Type: OpTypeDelete, Key: key}
+}
+
+// OpRange 构造一个范围读取操作，覆盖 [key, rangeEnd)
+func OpRange(key, rangeEnd string) Op {
+	return Op{Type: OpTypeRange, Key: key, RangeEnd: rangeEnd}
+}
+
+// GetWithRevision 获取键的当前值以及其 mod_rev，用于在读取之后构造 CAS 事务，例如
+// client.Txn(ctx).If(kvdb.CompareModRevisionEqual(key, rev)).Then(...).Commit()
+func (c *Client) GetWithRevision(ctx context.Context, key string) (value string, found bool, revision int64, err error) {
+	if !c.IsConnected() {
+		return "", false, 0, fmt.Errorf("client not connected")
+	}
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	var resp *pb.GetResponse
+	err = c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Get(rctx, &pb.GetRequest{Key: key})
+		return callErr
+	})
+	if err != nil {
+		return "", false, 0, fmt.Errorf("get operation failed: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return "", false, 0, fmt.Errorf("get operation failed: %s", resp.ErrorMessage)
+	}
+
+	return resp.Value, resp.Found, resp.ModRevision, nil
+}
+
+// TxnResult 是 Then/Else 分支中单个操作的执行结果
+type TxnResult struct {
+	Key      string
+	Value    string
+	Found    bool
+	Revision int64
+}
+
+// TxnResponse 是一次事务提交的结果
+type TxnResponse struct {
+	// Succeeded 为 true 表示 If 分支全部满足，Then 分支被执行；否则执行 Else 分支
+	Succeeded bool
+	Results   []TxnResult
+	Revision  int64
+}
+
+// TxnBuilder 以构建者模式组装一次 compare-and-swap 事务，用法与 etcd v3 的 Txn 一致：
+//
+//	resp, err := client.Txn(ctx).
+//		If(kvdb.CompareValueEqual("k", "old")).
+//		Then(kvdb.OpPut("k", "new")).
+//		Else(kvdb.OpGet("k")).
+//		Commit()
+type TxnBuilder struct {
+	client *Client
+	ctx    context.Context
+
+	cmps    []Compare
+	thenOps []Op
+	elseOps []Op
+}
+
+// Txn 开始构建一个新事务
+func (c *Client) Txn(ctx context.Context) *TxnBuilder {
+	return &TxnBuilder{client: c, ctx: ctx}
+}
+
+// If 添加事务的比较条件，全部条件满足时执行 Then 分支，否则执行 Else 分支
+func (t *TxnBuilder) If(cmps ...Compare) *TxnBuilder {
+	t.cmps = append(t.cmps, cmps...)
+	return t
+}
+
+// Then 设置条件满足时执行的操作
+func (t *TxnBuilder) Then(ops ...Op) *TxnBuilder {
+	t.thenOps = append(t.thenOps, ops...)
+	return t
+}
+
+// Else 设置条件不满足时执行的操作
+func (t *TxnBuilder) Else(ops ...Op) *TxnBuilder {
+	t.elseOps = append(t.elseOps, ops...)
+	return t
+}
+
+// Commit 原子地提交事务：服务端先评估 If 分支中的全部条件，再执行对应分支中的操作
+func (t *TxnBuilder) Commit() (*TxnResponse, error) {
+	c := t.client
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("client not connected")
+	}
+
+	reqCtx, cancel := context.WithTimeout(c.withAuth(t.ctx), c.config.RequestTimeout)
+	defer cancel()
+
+	req := &pb.TxnRequest{
+		Compare: make([]*pb.Compare, 0, len(t.cmps)),
+		Success: make([]*pb.TxnOp, 0, len(t.thenOps)),
+		Failure: make([]*pb.TxnOp, 0, len(t.elseOps)),
+	}
+	for _, cmp := range t.cmps {
+		req.Compare = append(req.Compare, toPBCompare(cmp))
+	}
+	for _, op := range t.thenOps {
+		req.Success = append(req.Success, toPBOp(op))
+	}
+	for _, op := range t.elseOps {
+		req.Failure = append(req.Failure, toPBOp(op))
+	}
+
+	var resp *pb.TxnResponse
+	err := c.withRPCRetry(reqCtx, func(rctx context.Context, rpcClient pb.KVDBServiceClient) error {
+		var callErr error
+		resp, callErr = rpcClient.Txn(rctx, req)
+		return callErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("txn operation failed: %w", err)
+	}
+	if resp.ErrorMessage != "" {
+		return nil, fmt.Errorf("txn operation failed: %s", resp.ErrorMessage)
+	}
+
+	results := make([]TxnResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, TxnResult{
+			Key:      r.Key,
+			Value:    r.Value,
+			Found:    r.Found,
+			Revision: r.ModRevision,
+		})
+	}
+
+	return &TxnResponse{Succeeded: resp.Succeeded, Results: results, Revision: resp.Revision}, nil
+}
+
+func toPBCompare(cmp Compare) *pb.Compare {
+	pbCmp := &pb.Compare{
+		Key:    cmp.Key,
+		Target: pb.Compare_Target(cmp.Target),
+		Result: pb.Compare_Result(cmp.Result),
+	}
+	switch cmp.Target {
+	case CompareValue:
+		pbCmp.Value = cmp.Value
+	case CompareVersion:
+		pbCmp.Version = cmp.Version
+	case CompareModRevision:
+		pbCmp.ModRevision = cmp.ModRevision
+	case CompareCreateRevision:
+		pbCmp.CreateRevision = cmp.CreateRevision
+	}
+	return pbCmp
+}
+
+func toPBOp(op Op) *pb.TxnOp {
+	return &pb.TxnOp{
+		Type:     pb.TxnOp_Type(op.Type),
+		Key:      op.Key,
+		Value:    op.Value,
+		RangeEnd: op.RangeEnd,
+	}
+}
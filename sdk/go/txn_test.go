@@ -0,0 +1,83 @@
+package kvdb
+
+import (
+	"testing"
+
+	pb "github.com/kvdb/proto"
+)
+
+func TestToPBCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		cmp  Compare
+		want *pb.Compare
+	}{
+		{
+			name: "value target only carries value",
+			cmp:  CompareValueEqual("k", "v"),
+			want: &pb.Compare{Key: "k", Target: pb.Compare_Target(CompareValue), Result: pb.Compare_Result(CompareEqual), Value: "v"},
+		},
+		{
+			name: "version target only carries version",
+			cmp:  CompareVersionEqual("k", 7),
+			want: &pb.Compare{Key: "k", Target: pb.Compare_Target(CompareVersion), Result: pb.Compare_Result(CompareEqual), Version: 7},
+		},
+		{
+			name: "mod revision target only carries mod revision",
+			cmp:  CompareModRevisionEqual("k", 42),
+			want: &pb.Compare{Key: "k", Target: pb.Compare_Target(CompareModRevision), Result: pb.Compare_Result(CompareEqual), ModRevision: 42},
+		},
+		{
+			name: "create revision target only carries create revision",
+			cmp:  CompareCreateRevisionEqual("k", 1),
+			want: &pb.Compare{Key: "k", Target: pb.Compare_Target(CompareCreateRevision), Result: pb.Compare_Result(CompareEqual), CreateRevision: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toPBCompare(tt.cmp)
+			if *got != *tt.want {
+				t.Fatalf("toPBCompare(%+v) = %+v, want %+v", tt.cmp, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToPBOp(t *testing.T) {
+	tests := []struct {
+		name string
+		op   Op
+		want *pb.TxnOp
+	}{
+		{
+			name: "put carries key and value",
+			op:   OpPut("k", "v"),
+			want: &pb.TxnOp{Type: pb.TxnOp_Type(OpTypePut), Key: "k", Value: "v"},
+		},
+		{
+			name: "get carries only the key",
+			op:   OpGet("k"),
+			want: &pb.TxnOp{Type: pb.TxnOp_Type(OpTypeGet), Key: "k"},
+		},
+		{
+			name: "delete carries only the key",
+			op:   OpDelete("k"),
+			want: &pb.TxnOp{Type: pb.TxnOp_Type(OpTypeDelete), Key: "k"},
+		},
+		{
+			name: "range carries key and range end",
+			op:   OpRange("a", "z"),
+			want: &pb.TxnOp{Type: pb.TxnOp_Type(OpTypeRange), Key: "a", RangeEnd: "z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toPBOp(tt.op)
+			if *got != *tt.want {
+				t.Fatalf("toPBOp(%+v) = %+v, want %+v", tt.op, got, tt.want)
+			}
+		})
+	}
+}